@@ -0,0 +1,101 @@
+// Copyright 2018 The Terraformer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+// resourceConnections declares, for each kind of imported resource, which
+// other kinds it may reference. Keys — both outer (source) and inner
+// (target) — are Terraform kubernetes provider resource type strings, i.e.
+// exactly what Kind.InitResources tags a terraform_utils.Resource with via
+// extractTfResourceName(s.Name) (kind.go:108). That's the only type string
+// this package ever attaches to a Resource, and it's what terraform_utils'
+// generic connector matches source/target resources by — the raw
+// Kubernetes discovery plural used to key GetSupportedService's result map
+// (e.g. "configmaps") is a different namespace and never matches here.
+//
+// Each value is a flat list of (sourceAttribute, targetAttribute) pairs —
+// checked independently, any pair matching adds a depends_on edge (and,
+// where the generic connector supports it, rewrites the source attribute
+// into an interpolated reference) — so `terraform apply` on the generated
+// HCL doesn't hit ordering failures from Deployments outrunning their
+// ConfigMaps/Secrets/ServiceAccounts, Ingresses outrunning their Services,
+// RoleBindings outrunning their Roles/ServiceAccounts, or PVCs outrunning
+// their StorageClasses.
+//
+// ownerReferences are deliberately not modeled here: the owner can be any
+// kind, and this table is keyed by concrete target-kind strings resolved
+// ahead of time, so there's no static (sourceKind, targetKind) pair to
+// attach an ownerReferences rule to. Wiring that up needs a connector that
+// walks each resource's actual ownerReferences at resolve time rather than
+// a static declaration, which is out of scope here.
+//
+// The only install ordering this produces is the depends_on edges from the
+// pairs below. There is no category-wide ordering: nothing here makes
+// namespaces, CRDs or kinds absent from this table install before others.
+var resourceConnections = map[string]map[string][]string{
+	"kubernetes_deployment": {
+		"kubernetes_config_map": {
+			"spec.0.template.0.spec.0.container.0.env_from.0.config_map_ref.0.name", "metadata.0.name",
+			"spec.0.template.0.spec.0.volume.0.config_map.0.name", "metadata.0.name",
+		},
+		"kubernetes_secret": {
+			"spec.0.template.0.spec.0.container.0.env_from.0.secret_ref.0.name", "metadata.0.name",
+			"spec.0.template.0.spec.0.volume.0.secret.0.secret_name", "metadata.0.name",
+		},
+		"kubernetes_service_account": {"spec.0.template.0.spec.0.service_account_name", "metadata.0.name"},
+	},
+	"kubernetes_daemonset": {
+		"kubernetes_config_map": {
+			"spec.0.template.0.spec.0.container.0.env_from.0.config_map_ref.0.name", "metadata.0.name",
+			"spec.0.template.0.spec.0.volume.0.config_map.0.name", "metadata.0.name",
+		},
+		"kubernetes_secret": {
+			"spec.0.template.0.spec.0.container.0.env_from.0.secret_ref.0.name", "metadata.0.name",
+			"spec.0.template.0.spec.0.volume.0.secret.0.secret_name", "metadata.0.name",
+		},
+		"kubernetes_service_account": {"spec.0.template.0.spec.0.service_account_name", "metadata.0.name"},
+	},
+	"kubernetes_stateful_set": {
+		"kubernetes_config_map": {
+			"spec.0.template.0.spec.0.container.0.env_from.0.config_map_ref.0.name", "metadata.0.name",
+			"spec.0.template.0.spec.0.volume.0.config_map.0.name", "metadata.0.name",
+		},
+		"kubernetes_secret": {
+			"spec.0.template.0.spec.0.container.0.env_from.0.secret_ref.0.name", "metadata.0.name",
+			"spec.0.template.0.spec.0.volume.0.secret.0.secret_name", "metadata.0.name",
+		},
+		"kubernetes_service_account": {"spec.0.template.0.spec.0.service_account_name", "metadata.0.name"},
+	},
+	"kubernetes_ingress": {
+		"kubernetes_service": {"spec.0.rule.0.http.0.path.0.backend.0.service.0.name", "metadata.0.name"},
+	},
+	"kubernetes_role_binding": {
+		"kubernetes_role":            {"role_ref.0.name", "metadata.0.name"},
+		"kubernetes_service_account": {"subject.0.name", "metadata.0.name"},
+	},
+	"kubernetes_cluster_role_binding": {
+		"kubernetes_cluster_role":    {"role_ref.0.name", "metadata.0.name"},
+		"kubernetes_service_account": {"subject.0.name", "metadata.0.name"},
+	},
+	"kubernetes_persistent_volume_claim": {
+		"kubernetes_storage_class": {"spec.0.storage_class_name", "metadata.0.name"},
+	},
+}
+
+// GetResourceConnections returns the static cross-reference rules
+// terraform_utils uses to wire up depends_on between imported Kubernetes
+// resources.
+func (p KubernetesProvider) GetResourceConnections() map[string]map[string][]string {
+	return resourceConnections
+}
@@ -0,0 +1,71 @@
+// Copyright 2018 The Terraformer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import "testing"
+
+// kindByResourceConnectionsType maps every kubernetes_* type string used in
+// resourceConnections back to the Kind it would be produced from, so the
+// test below can confirm each key is exactly what Kind.InitResources tags a
+// real Resource with.
+var kindByResourceConnectionsType = map[string]string{
+	"kubernetes_deployment":              "Deployment",
+	"kubernetes_daemonset":               "DaemonSet",
+	"kubernetes_stateful_set":            "StatefulSet",
+	"kubernetes_config_map":              "ConfigMap",
+	"kubernetes_secret":                  "Secret",
+	"kubernetes_service_account":         "ServiceAccount",
+	"kubernetes_service":                 "Service",
+	"kubernetes_ingress":                 "Ingress",
+	"kubernetes_role_binding":            "RoleBinding",
+	"kubernetes_role":                    "Role",
+	"kubernetes_cluster_role_binding":    "ClusterRoleBinding",
+	"kubernetes_cluster_role":            "ClusterRole",
+	"kubernetes_persistent_volume_claim": "PersistentVolumeClaim",
+	"kubernetes_storage_class":           "StorageClass",
+}
+
+// TestResourceConnectionsUseTerraformResourceTypes guards against
+// resourceConnections regressing to discovery-plural keys (e.g.
+// "configmaps"), which never match a real terraform_utils.Resource's type
+// and make GetResourceConnections() silently a no-op. Every source and
+// target key must equal extractTfResourceName(kind) for the Kind that
+// would actually produce a resource of that type, exactly as
+// Kind.InitResources computes it.
+func TestResourceConnectionsUseTerraformResourceTypes(t *testing.T) {
+	connections := KubernetesProvider{}.GetResourceConnections()
+
+	for sourceType, targets := range connections {
+		sourceKind, known := kindByResourceConnectionsType[sourceType]
+		if !known {
+			t.Errorf("resourceConnections source key %q has no entry in kindByResourceConnectionsType", sourceType)
+			continue
+		}
+		if got := extractTfResourceName(sourceKind); got != sourceType {
+			t.Errorf("resourceConnections source key %q does not match extractTfResourceName(%q) = %q", sourceType, sourceKind, got)
+		}
+
+		for targetType := range targets {
+			targetKind, known := kindByResourceConnectionsType[targetType]
+			if !known {
+				t.Errorf("resourceConnections target key %q under %q has no entry in kindByResourceConnectionsType", targetType, sourceType)
+				continue
+			}
+			if got := extractTfResourceName(targetKind); got != targetType {
+				t.Errorf("resourceConnections target key %q does not match extractTfResourceName(%q) = %q", targetType, targetKind, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2018 The Terraformer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSanitizePrefersLastAppliedConfiguration(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata": map[string]interface{}{
+			"name": "web",
+			"annotations": map[string]interface{}{
+				lastAppliedConfigAnnotation: `{"apiVersion":"v1","kind":"Service","metadata":{"name":"web"},"spec":{"selector":{"app":"web"}}}`,
+			},
+			"resourceVersion": "12345",
+		},
+		"spec": map[string]interface{}{
+			"clusterIP": "10.0.0.1",
+			"selector":  map[string]interface{}{"app": "web"},
+		},
+	}}
+
+	got := sanitize(obj, SanitizeRules{})
+
+	if _, found, _ := unstructured.NestedString(got.Object, "spec", "clusterIP"); found {
+		t.Errorf("expected clusterIP to be absent from the recovered last-applied-configuration, got present")
+	}
+	if _, found, _ := unstructured.NestedString(got.Object, "metadata", "resourceVersion"); found {
+		t.Errorf("expected resourceVersion to be absent from the recovered last-applied-configuration, got present")
+	}
+}
+
+func TestSanitizeAppliesDefaultAndCustomRulesWithoutLastApplied(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata": map[string]interface{}{
+			"name":            "web",
+			"resourceVersion": "12345",
+		},
+		"spec": map[string]interface{}{
+			"clusterIP": "10.0.0.1",
+			"ports": []interface{}{
+				map[string]interface{}{"port": int64(80), "nodePort": int64(31000)},
+			},
+		},
+	}}
+
+	rules := SanitizeRules{"/v1, Kind=Service": {"spec.ports"}}
+	got := sanitize(obj, rules)
+
+	if _, found, _ := unstructured.NestedString(got.Object, "metadata", "resourceVersion"); found {
+		t.Errorf("expected defaultSanitizeDenylist to strip resourceVersion, got present")
+	}
+	if _, found, _ := unstructured.NestedString(got.Object, "spec", "clusterIP"); found {
+		t.Errorf("expected defaultKindSanitizeRules to strip spec.clusterIP for a Service, got present")
+	}
+	if _, found, _ := unstructured.NestedSlice(got.Object, "spec", "ports"); found {
+		t.Errorf("expected the operator-supplied rule to strip spec.ports, got present")
+	}
+}
+
+func TestStripAutoNodePorts(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"ports": []interface{}{
+				map[string]interface{}{"port": int64(80), "nodePort": int64(31000)},
+				map[string]interface{}{"port": int64(443)},
+			},
+		},
+	}}
+
+	stripAutoNodePorts(obj)
+
+	ports, _, _ := unstructured.NestedSlice(obj.Object, "spec", "ports")
+	for _, p := range ports {
+		port := p.(map[string]interface{})
+		if _, ok := port["nodePort"]; ok {
+			t.Errorf("expected nodePort to be stripped from %v", port)
+		}
+	}
+}
+
+func TestStripServiceAccountTokenVolumes(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{
+					"name": "app",
+					"volumeMounts": []interface{}{
+						map[string]interface{}{"name": "data", "mountPath": "/data"},
+						map[string]interface{}{"name": "kube-api-access-abcde", "mountPath": "/var/run/secrets/kubernetes.io/serviceaccount"},
+					},
+				},
+			},
+			"volumes": []interface{}{
+				map[string]interface{}{"name": "data"},
+				map[string]interface{}{"name": "kube-api-access-abcde"},
+			},
+		},
+	}}
+
+	stripServiceAccountTokenVolumes(obj)
+
+	volumes, _, _ := unstructured.NestedSlice(obj.Object, "spec", "volumes")
+	if len(volumes) != 1 {
+		t.Fatalf("expected exactly one volume to remain, got %d: %v", len(volumes), volumes)
+	}
+	if name, _, _ := unstructured.NestedString(volumes[0].(map[string]interface{}), "name"); name != "data" {
+		t.Errorf("expected the remaining volume to be %q, got %q", "data", name)
+	}
+
+	containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "containers")
+	mounts, _, _ := unstructured.NestedSlice(containers[0].(map[string]interface{}), "volumeMounts")
+	if len(mounts) != 1 {
+		t.Fatalf("expected exactly one volumeMount to remain, got %d: %v", len(mounts), mounts)
+	}
+	if name, _, _ := unstructured.NestedString(mounts[0].(map[string]interface{}), "name"); name != "data" {
+		t.Errorf("expected the remaining volumeMount to be %q, got %q", "data", name)
+	}
+}